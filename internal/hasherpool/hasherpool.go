@@ -0,0 +1,47 @@
+// Package hasherpool provides a width-indexed sync.Pool, shared by the
+// poseidon and poseidon2 packages so that pooling their respective per-width
+// Hasher types doesn't require duplicating the same pool bookkeeping (one
+// sync.Pool per width, a constructor closure per width) in each package.
+package hasherpool
+
+import "sync"
+
+// MaxWidth is one more than the largest Poseidon/Poseidon2 width (t) either
+// package supports.
+const MaxWidth = 18
+
+// Pool holds one sync.Pool per width in [1, MaxWidth), each backed by a
+// shared constructor.
+type Pool struct {
+	newFn func(width int) interface{}
+	pools [MaxWidth]sync.Pool
+}
+
+// New returns a Pool whose entry for width w lazily constructs a value via
+// newFn(w) whenever that width's pool is empty.
+func New(newFn func(width int) interface{}) *Pool {
+	p := &Pool{newFn: newFn}
+	for w := 1; w < MaxWidth; w++ {
+		w := w
+		p.pools[w].New = func() interface{} { return newFn(w) }
+	}
+	return p
+}
+
+// Get borrows a value for width w. Widths outside [1, MaxWidth) are not
+// pooled; Get falls back to calling newFn(w) directly for those.
+func (p *Pool) Get(w int) interface{} {
+	if w <= 0 || w >= MaxWidth {
+		return p.newFn(w)
+	}
+	return p.pools[w].Get()
+}
+
+// Put returns a value for width w to the pool. Widths outside [1, MaxWidth)
+// are silently dropped, matching Get's fallback for those widths.
+func (p *Pool) Put(w int, v interface{}) {
+	if w <= 0 || w >= MaxWidth {
+		return
+	}
+	p.pools[w].Put(v)
+}
@@ -0,0 +1,32 @@
+package poseidon2
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// BenchmarkHashT3 and BenchmarkPoseidonHashT3 let `go test -bench` compare
+// poseidon2's cheaper linear layer against poseidon for t=3 (two inputs),
+// the width the request asked this package to roughly double the
+// throughput of.
+func BenchmarkHashT3(b *testing.B) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Hash(in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPoseidonHashT3(b *testing.B) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := poseidon.Hash(in); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package poseidon2
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+)
+
+// poseidon2Constants holds, for every supported width t (index t-2): the
+// external-round constants (rc, NROUNDSF*t entries added to the full
+// state), the internal-round constants (rc0, one entry per partial round,
+// added to state[0] only) and the M_I diagonal (mu, t entries).
+//
+// NOTE: poseidon's c.c/c.s/c.m/c.p tables are generated offline by the
+// reference Grain LFSR script and embedded as literals. These tables are
+// instead derived here at init time by a domain-separated hash expansion,
+// standing in for that offline generator, and are NOT the audited Poseidon2
+// constants for BN254. Every exported entry point that would run them
+// (Hash, HashWithState, HashWithStateEx, HashEx, Hasher.Sum, and a Sponge's
+// Write, Sum and SumBig once they run the permutation) panics unless
+// UseUnauditedPlaceholderConstants has been called first, so this package
+// cannot be used as a real hash by accident; it exists today to benchmark
+// the new linear layer, and for that reason lives under internal/ rather
+// than being importable as a general-purpose hash. Replace this file with
+// the audited reference constants before promoting the package out of
+// internal/ for production use.
+type poseidon2Constants struct {
+	rc  [][]*ff.Element
+	rc0 [][]*ff.Element
+	mu  [][]*ff.Element
+}
+
+var c2 poseidon2Constants
+
+var unauditedConstantsAcknowledged int32
+
+// UseUnauditedPlaceholderConstants acknowledges that this build of poseidon2
+// runs on the placeholder round constants and diagonal described in
+// constants.go rather than audited Poseidon2 parameters, and unlocks Hash,
+// HashWithState, HashWithStateEx, HashEx, Hasher.Sum and the Sponge to run
+// anyway. Call
+// this only to benchmark or exercise the new linear layer; do not call it in
+// production until constants.go embeds the real, audited constants.
+func UseUnauditedPlaceholderConstants() {
+	atomic.StoreInt32(&unauditedConstantsAcknowledged, 1)
+}
+
+// checkConstantsAcknowledged panics if UseUnauditedPlaceholderConstants has
+// not been called, so a caller can't silently hash with placeholder
+// constants.
+func checkConstantsAcknowledged() {
+	if atomic.LoadInt32(&unauditedConstantsAcknowledged) == 0 {
+		panic("poseidon2: round constants in this build are an unaudited placeholder (see constants.go); " +
+			"call poseidon2.UseUnauditedPlaceholderConstants() to acknowledge this and proceed anyway")
+	}
+}
+
+func init() {
+	n := len(NROUNDSP)
+	c2.rc = make([][]*ff.Element, n)
+	c2.rc0 = make([][]*ff.Element, n)
+	c2.mu = make([][]*ff.Element, n)
+
+	for idx := 0; idx < n; idx++ {
+		t := idx + 2
+		c2.rc[idx] = expandConstants(fmt.Sprintf("Poseidon2_rc_t%d", t), NROUNDSF*t)
+		c2.rc0[idx] = expandConstants(fmt.Sprintf("Poseidon2_rc0_t%d", t), NROUNDSP[idx])
+		c2.mu[idx] = expandNonZeroConstants(fmt.Sprintf("Poseidon2_mu_t%d", t), t)
+	}
+}
+
+// expandConstants derives n field elements from repeated SHA-256(label || i).
+func expandConstants(label string, n int) []*ff.Element {
+	out := make([]*ff.Element, n)
+	for i := 0; i < n; i++ {
+		out[i] = ff.NewElement().SetBytesLessMod(expand(label, i))
+	}
+	return out
+}
+
+// expandNonZeroConstants is like expandConstants, but re-derives any element
+// that happens to hash to zero: M_I's diagonal entries must be nonzero for
+// M_I to be invertible.
+func expandNonZeroConstants(label string, n int) []*ff.Element {
+	out := make([]*ff.Element, n)
+	v := new(big.Int)
+	for i := 0; i < n; i++ {
+		e := ff.NewElement()
+		for attempt := 0; ; attempt++ {
+			e.SetBytesLessMod(expand(fmt.Sprintf("%s_retry%d", label, attempt), i))
+			if e.ToBigIntRegular(v).Sign() != 0 {
+				break
+			}
+		}
+		out[i] = e
+	}
+	return out
+}
+
+// expand returns SHA-256(label || big-endian(i)).
+func expand(label string, i int) []byte {
+	h := sha256.New()
+	h.Write([]byte(label))
+	var idx [8]byte
+	binary.BigEndian.PutUint64(idx[:], uint64(i))
+	h.Write(idx[:])
+	return h.Sum(nil)
+}
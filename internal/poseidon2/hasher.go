@@ -0,0 +1,96 @@
+package poseidon2
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+	"github.com/iden3/go-iden3-crypto/internal/hasherpool"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// Hasher is the Poseidon2 analogue of poseidon.Hasher: it owns a per-width
+// state buffer and a single reusable ff.Element temporary, so that running
+// the permutation does not allocate once the Hasher has been constructed.
+// Poseidon2 needs no scratch buffer the way poseidon.Hasher does, since
+// mixExternal/mixInternal never build a full matrix-vector product.
+//
+// A Hasher is not safe for concurrent use.
+type Hasher struct {
+	t     int
+	state []*ff.Element
+	tmp   *ff.Element
+	n     int // number of inputs written since the last Reset
+}
+
+// NewHasher allocates a Hasher for width t (t = number of inputs + 1). It
+// panics if t is out of range, mirroring poseidon.NewHasher.
+func NewHasher(t int) *Hasher {
+	if t < 2 || t > len(NROUNDSP)+1 {
+		panic(fmt.Sprintf("poseidon2: invalid width %d, must be in [2, %d]", t, len(NROUNDSP)+1))
+	}
+	h := &Hasher{
+		t:     t,
+		state: make([]*ff.Element, t),
+		tmp:   new(ff.Element),
+	}
+	for i := 0; i < t; i++ {
+		h.state[i] = new(ff.Element)
+	}
+	return h
+}
+
+// Reset clears the written inputs so the Hasher can be reused for a new hash.
+func (h *Hasher) Reset() {
+	for i := 0; i < h.t; i++ {
+		h.state[i].SetZero()
+	}
+	h.n = 0
+}
+
+// Write appends inputs to the state, in order. The total number of elements
+// written since the last Reset must not exceed t-1.
+func (h *Hasher) Write(inputs []*big.Int) error {
+	if h.n+len(inputs) > h.t-1 {
+		return fmt.Errorf("too many inputs for width %d: already have %d, got %d more", h.t, h.n, len(inputs))
+	}
+	if !utils.CheckBigIntArrayInField(inputs) {
+		return fmt.Errorf("inputs values not inside Finite Field")
+	}
+	for _, in := range inputs {
+		h.state[1+h.n].SetBigInt(in)
+		h.n++
+	}
+	return nil
+}
+
+// Sum runs the Poseidon2 permutation over the written state and writes the
+// digest into dst, returning dst (allocating a fresh *big.Int if dst is
+// nil), mirroring poseidon.Hasher.Sum. It does not reset the Hasher; call
+// Reset before writing a new hash.
+func (h *Hasher) Sum(dst *big.Int) *big.Int {
+	if dst == nil {
+		dst = new(big.Int)
+	}
+	state := hashElements(h.state, h.tmp)
+	state[0].ToBigIntRegular(dst)
+	return dst
+}
+
+// hasherPool is a width-indexed pool of *Hasher, so that Hash and the
+// Sponge can transparently borrow a warm Hasher instead of allocating a
+// state slice on every call. It's built on the same hasherpool.Pool that
+// poseidon uses for its own Hasher type.
+var hasherPool = hasherpool.New(func(t int) interface{} { return NewHasher(t) })
+
+// getHasher borrows a reset Hasher of width t from the pool.
+func getHasher(t int) *Hasher {
+	h := hasherPool.Get(t).(*Hasher)
+	h.Reset()
+	return h
+}
+
+// putHasher returns a Hasher of width t to the pool.
+func putHasher(t int, h *Hasher) {
+	hasherPool.Put(t, h)
+}
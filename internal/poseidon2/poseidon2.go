@@ -0,0 +1,234 @@
+// Package poseidon2 implements the Poseidon2 permutation
+// (https://eprint.iacr.org/2023/323.pdf), a faster drop-in replacement for
+// the Poseidon permutation in the sibling poseidon package. It replaces
+// Poseidon's dense MDS matrix with a much cheaper linear layer: a fixed
+// circulant (or block-circulant) matrix M_E in the external (full) rounds,
+// and a diagonal matrix M_I plus a broadcast sum in the internal (partial)
+// rounds. Round counts, the alpha=5 S-box and the supported widths
+// (t = 2..17, i.e. up to 16 inputs) match poseidon.
+//
+// This package lives under internal/ and is not a general-purpose hash: the
+// round constants and M_I diagonal embedded in constants.go are an unaudited
+// placeholder, not the reference Poseidon2 parameters for BN254 (see that
+// file's doc comment). Every hashing entry point in this package therefore
+// panics until the caller calls UseUnauditedPlaceholderConstants to
+// acknowledge that and opt in. It exists today to benchmark the cheaper
+// linear layer against poseidon; promote it to a public package once
+// constants.go embeds the real, audited constants.
+package poseidon2
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+	"github.com/iden3/go-iden3-crypto/poseidon"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// NROUNDSF is the number of full (external) rounds, matching poseidon.NROUNDSF.
+const NROUNDSF = poseidon.NROUNDSF
+
+// NROUNDSP is the number of partial (internal) rounds per width, matching
+// poseidon.NROUNDSP.
+var NROUNDSP = poseidon.NROUNDSP
+
+const spongeChunkSize = 31
+const spongeInputs = 16
+
+var big5 = big.NewInt(5)
+
+func exp5(a *ff.Element) {
+	a.Exp(*a, big5)
+}
+
+func exp5state(state []*ff.Element) {
+	for i := 0; i < len(state); i++ {
+		exp5(state[i])
+	}
+}
+
+// arkFull adds the full-width external-round constants of round `round` to
+// every state element.
+func arkFull(state []*ff.Element, rc []*ff.Element, round int) {
+	t := len(state)
+	for i := 0; i < t; i++ {
+		state[i].Add(state[i], rc[round*t+i])
+	}
+}
+
+// applyM4 multiplies a 4-element block in place by the Poseidon2 base
+// matrix M4 = circ(2,1,1,1), using the identity 2*x_i + sum_{j!=i} x_j =
+// x_i + sum(block) so no coefficient ever needs to be materialized.
+func applyM4(block []*ff.Element) {
+	var sum ff.Element
+	sum.SetZero()
+	for i := 0; i < 4; i++ {
+		sum.Add(&sum, block[i])
+	}
+	for i := 0; i < 4; i++ {
+		block[i].Add(block[i], &sum)
+	}
+}
+
+// mixExternal applies the external linear layer M_E in place. For t a
+// multiple of 4 this is the block-circulant construction from the Poseidon2
+// paper: M4 applied to each 4-wide block, followed by adding, to every
+// element of a lane, the sum of that lane across all blocks. For every
+// other t it falls back to the flat circulant circ(2,1,...,1).
+func mixExternal(state []*ff.Element) {
+	t := len(state)
+	if t%4 != 0 {
+		var sum ff.Element
+		sum.SetZero()
+		for i := 0; i < t; i++ {
+			sum.Add(&sum, state[i])
+		}
+		for i := 0; i < t; i++ {
+			state[i].Add(state[i], &sum)
+		}
+		return
+	}
+
+	nBlocks := t / 4
+	for b := 0; b < nBlocks; b++ {
+		applyM4(state[b*4 : b*4+4])
+	}
+
+	var laneSum [4]ff.Element
+	for j := 0; j < 4; j++ {
+		laneSum[j].SetZero()
+	}
+	for b := 0; b < nBlocks; b++ {
+		for j := 0; j < 4; j++ {
+			laneSum[j].Add(&laneSum[j], state[b*4+j])
+		}
+	}
+	for b := 0; b < nBlocks; b++ {
+		for j := 0; j < 4; j++ {
+			state[b*4+j].Add(state[b*4+j], &laneSum[j])
+		}
+	}
+}
+
+// mixInternal applies M_I = diag(mu_0, ..., mu_{t-1}) plus the broadcast-sum
+// correction that the Poseidon2 paper uses in place of a full matrix-vector
+// product: state[i] = mu_i*state[i] + sum(state). tmp is an arena-backed
+// temporary owned by the caller (see Hasher) so this does not allocate.
+func mixInternal(state []*ff.Element, mu []*ff.Element, tmp *ff.Element) {
+	var sum ff.Element
+	sum.SetZero()
+	for i := 0; i < len(state); i++ {
+		sum.Add(&sum, state[i])
+	}
+	for i := 0; i < len(state); i++ {
+		tmp.Mul(mu[i], state[i])
+		state[i].Add(tmp, &sum)
+	}
+}
+
+// hashElements runs the Poseidon2 permutation over state in place: an
+// initial external linear layer, nRoundsF/2 full rounds, nRoundsP partial
+// rounds and a final nRoundsF/2 full rounds. tmp is an arena-backed
+// temporary (see Hasher); state and tmp must not alias each other.
+//
+// It panics unless UseUnauditedPlaceholderConstants has been called, since
+// every entry point in this package (Hash, HashWithState, HashWithStateEx,
+// HashEx, Hasher.Sum, Sponge) bottoms out here and the round constants in
+// constants.go are an unaudited placeholder (see its doc comment).
+func hashElements(state []*ff.Element, tmp *ff.Element) []*ff.Element {
+	checkConstantsAcknowledged()
+	t := len(state)
+	nRoundsF := NROUNDSF
+	nRoundsP := NROUNDSP[t-2]
+	rc := c2.rc[t-2]
+	rc0 := c2.rc0[t-2]
+	mu := c2.mu[t-2]
+
+	mixExternal(state)
+	for i := 0; i < nRoundsF/2; i++ {
+		arkFull(state, rc, i)
+		exp5state(state)
+		mixExternal(state)
+	}
+
+	for i := 0; i < nRoundsP; i++ {
+		state[0].Add(state[0], rc0[i])
+		exp5(state[0])
+		mixInternal(state, mu, tmp)
+	}
+
+	for i := 0; i < nRoundsF/2; i++ {
+		arkFull(state, rc, nRoundsF/2+i)
+		exp5state(state)
+		mixExternal(state)
+	}
+
+	return state
+}
+
+// HashWithState computes the Poseidon2 hash for the given inputs and initState.
+func HashWithState(inpBI []*big.Int, initState *big.Int) (*big.Int, error) {
+	res, err := HashWithStateEx(inpBI, initState, 1)
+	if err != nil {
+		return nil, err
+	}
+	return res[0], nil
+}
+
+// HashWithStateEx computes the Poseidon2 permutation for the given inputs
+// and initState, returning the first nOuts elements of the resulting state.
+func HashWithStateEx(inpBI []*big.Int, initState *big.Int, nOuts int) ([]*big.Int, error) {
+	t := len(inpBI) + 1
+	if len(inpBI) == 0 || len(inpBI) > len(NROUNDSP) {
+		return nil, fmt.Errorf("invalid inputs length %d, max %d", len(inpBI), len(NROUNDSP))
+	}
+	if !utils.CheckBigIntArrayInField(inpBI) {
+		return nil, errors.New("inputs values not inside Finite Field")
+	}
+	if nOuts < 1 || nOuts > t {
+		return nil, fmt.Errorf("invalid nOuts %d, min 1, max %d", nOuts, t)
+	}
+	if !utils.CheckBigIntInField(initState) {
+		return nil, errors.New("initState values not inside Finite Field")
+	}
+
+	state := make([]*ff.Element, t)
+	state[0] = ff.NewElement().SetBigInt(initState)
+	for i, in := range inpBI {
+		state[i+1] = ff.NewElement().SetBigInt(in)
+	}
+
+	state = hashElements(state, new(ff.Element))
+
+	r := make([]*big.Int, nOuts)
+	for i := 0; i < nOuts; i++ {
+		r[i] = big.NewInt(0)
+		state[i].ToBigIntRegular(r[i])
+	}
+	return r, nil
+}
+
+// Hash computes the Poseidon2 hash for the given inputs. It borrows a
+// pooled *Hasher, so repeated calls skip the per-call state allocation once
+// the pool is warm; the *big.Int it returns is still a fresh allocation.
+func Hash(inpBI []*big.Int) (*big.Int, error) {
+	if len(inpBI) == 0 || len(inpBI) > len(NROUNDSP) {
+		return nil, fmt.Errorf("invalid inputs length %d, max %d", len(inpBI), len(NROUNDSP))
+	}
+	t := len(inpBI) + 1
+	h := getHasher(t)
+	defer putHasher(t, h)
+
+	if err := h.Write(inpBI); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// HashEx computes the Poseidon2 hash for the given inputs and returns the
+// first nOuts outputs that include intermediate states.
+func HashEx(inpBI []*big.Int, nOuts int) ([]*big.Int, error) {
+	return HashWithStateEx(inpBI, big.NewInt(0), nOuts)
+}
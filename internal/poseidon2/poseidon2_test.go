@@ -0,0 +1,93 @@
+package poseidon2
+
+import (
+	"math/big"
+	"sync/atomic"
+	"testing"
+)
+
+func init() {
+	UseUnauditedPlaceholderConstants()
+}
+
+func TestHashIsDeterministic(t *testing.T) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	h1, err := Hash(in)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	h2, err := Hash(in)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	if h1.Cmp(h2) != 0 {
+		t.Fatalf("Hash(%v) = %v and %v, want equal", in, h1, h2)
+	}
+}
+
+func TestHashMatchesHashWithState(t *testing.T) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	viaHash, err := Hash(in)
+	if err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+	viaState, err := HashWithState(in, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("HashWithState: %v", err)
+	}
+	if viaHash.Cmp(viaState) != 0 {
+		t.Fatalf("Hash = %v, HashWithState(_, 0) = %v, want equal", viaHash, viaState)
+	}
+}
+
+func TestHashWithStateDiffersByInitState(t *testing.T) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+	h0, err := HashWithState(in, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("HashWithState: %v", err)
+	}
+	h1, err := HashWithState(in, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("HashWithState: %v", err)
+	}
+	if h0.Cmp(h1) == 0 {
+		t.Fatalf("HashWithState with different initState produced the same digest: %v", h0)
+	}
+}
+
+func TestHashRejectsOutOfRangeInputs(t *testing.T) {
+	if _, err := Hash(nil); err == nil {
+		t.Fatal("Hash(nil) should have errored")
+	}
+	tooMany := make([]*big.Int, len(NROUNDSP)+1)
+	for i := range tooMany {
+		tooMany[i] = big.NewInt(int64(i))
+	}
+	if _, err := Hash(tooMany); err == nil {
+		t.Fatal("Hash with more inputs than supported should have errored")
+	}
+}
+
+func TestNewHasherRejectsInvalidWidth(t *testing.T) {
+	for _, tc := range []int{-1, 0, 1, len(NROUNDSP) + 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewHasher(%d) should have panicked", tc)
+				}
+			}()
+			NewHasher(tc)
+		}()
+	}
+}
+
+func TestHashPanicsWithoutAcknowledgment(t *testing.T) {
+	defer func() {
+		atomic.StoreInt32(&unauditedConstantsAcknowledged, 1) // restore for the rest of the suite
+		if r := recover(); r == nil {
+			t.Fatal("Hash should have panicked without UseUnauditedPlaceholderConstants")
+		}
+	}()
+	atomic.StoreInt32(&unauditedConstantsAcknowledged, 0)
+	_, _ = Hash([]*big.Int{big.NewInt(1), big.NewInt(2)})
+}
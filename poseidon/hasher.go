@@ -0,0 +1,112 @@
+package poseidon
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+	"github.com/iden3/go-iden3-crypto/internal/hasherpool"
+	"github.com/iden3/go-iden3-crypto/utils"
+)
+
+// Hasher owns a per-width state buffer, scratch buffer and two reusable
+// ff.Element temporaries, so that running the Poseidon permutation does not
+// allocate once the Hasher has been constructed. It is the zero-allocation
+// counterpart of Hash/HashWithStateEx: callers that hash repeatedly (Merkle
+// tree building, sponge construction over large messages) should keep one
+// around and Reset it between hashes instead of calling Hash in a loop.
+//
+// A Hasher is not safe for concurrent use.
+type Hasher struct {
+	t         int
+	state     []*ff.Element
+	scratch   []*ff.Element
+	tmp       *ff.Element
+	newState0 *ff.Element
+	n         int // number of inputs written since the last Reset
+}
+
+// NewHasher allocates a Hasher for width t (t = number of inputs + 1),
+// pre-sizing every buffer it needs so that Write and Sum never allocate. It
+// panics if t is out of range, in keeping with NewSponge rejecting invalid
+// configuration up front rather than letting a caller hit a confusing panic
+// later inside Sum.
+func NewHasher(t int) *Hasher {
+	if t < 2 || t > len(NROUNDSP)+1 {
+		panic(fmt.Sprintf("poseidon: invalid width %d, must be in [2, %d]", t, len(NROUNDSP)+1))
+	}
+	h := &Hasher{
+		t:         t,
+		state:     make([]*ff.Element, t),
+		scratch:   make([]*ff.Element, t),
+		tmp:       new(ff.Element),
+		newState0: new(ff.Element),
+	}
+	for i := 0; i < t; i++ {
+		h.state[i] = new(ff.Element)
+		h.scratch[i] = new(ff.Element)
+	}
+	return h
+}
+
+// Reset clears the written inputs so the Hasher can be reused for a new hash.
+func (h *Hasher) Reset() {
+	for i := 0; i < h.t; i++ {
+		h.state[i].SetZero()
+	}
+	h.n = 0
+}
+
+// Write appends inputs to the state, in order. The total number of elements
+// written since the last Reset must not exceed t-1.
+func (h *Hasher) Write(inputs []*big.Int) error {
+	if h.n+len(inputs) > h.t-1 {
+		return fmt.Errorf("too many inputs for width %d: already have %d, got %d more", h.t, h.n, len(inputs))
+	}
+	if !utils.CheckBigIntArrayInField(inputs) {
+		return fmt.Errorf("inputs values not inside Finite Field")
+	}
+	for _, in := range inputs {
+		h.state[1+h.n].SetBigInt(in)
+		h.n++
+	}
+	return nil
+}
+
+// Sum runs the Poseidon permutation over the written state and writes the
+// digest into dst, returning dst. If dst is nil, a fresh *big.Int is
+// allocated. A caller that reuses both the Hasher (via Reset) and dst across
+// calls drives Write+Sum with zero heap allocations after a warm-up call
+// (see TestHasherSumAllocs); Sum(nil) keeps the old allocating behavior for
+// callers that just want a result.
+//
+// Sum does not reset the Hasher; call Reset before writing a new hash.
+func (h *Hasher) Sum(dst *big.Int) *big.Int {
+	if dst == nil {
+		dst = new(big.Int)
+	}
+	for i := 0; i < h.t; i++ {
+		h.scratch[i].SetZero()
+	}
+	state := hashElements(h.state, h.scratch, h.tmp, h.newState0)
+	state[0].ToBigIntRegular(dst)
+	return dst
+}
+
+// hasherPool is a width-indexed pool of *Hasher, so that Hash, HashBytesX
+// and SpongeHash can transparently borrow a warm Hasher instead of
+// allocating state/scratch slices on every call. It's built on the same
+// hasherpool.Pool that poseidon2 uses for its own Hasher type.
+var hasherPool = hasherpool.New(func(t int) interface{} { return NewHasher(t) })
+
+// getHasher borrows a reset Hasher of width t from the pool.
+func getHasher(t int) *Hasher {
+	h := hasherPool.Get(t).(*Hasher)
+	h.Reset()
+	return h
+}
+
+// putHasher returns a Hasher of width t to the pool.
+func putHasher(t int, h *Hasher) {
+	hasherPool.Put(t, h)
+}
@@ -0,0 +1,64 @@
+package poseidon
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestHasherSumAllocs(t *testing.T) {
+	h := NewHasher(3)
+	dst := new(big.Int)
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+
+	// warm up: the first call may still populate internal state.
+	h.Reset()
+	if err := h.Write(in); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	h.Sum(dst)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		h.Reset()
+		if err := h.Write(in); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		h.Sum(dst)
+	})
+	if allocs != 0 {
+		t.Fatalf("Hasher.Write+Sum(dst) allocated %v times per run, want 0", allocs)
+	}
+}
+
+func TestNewHasherRejectsInvalidWidth(t *testing.T) {
+	for _, tc := range []int{-1, 0, 1, len(NROUNDSP) + 2} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewHasher(%d) should have panicked", tc)
+				}
+			}()
+			NewHasher(tc)
+		}()
+	}
+}
+
+func TestHashAllocatesOnlyTheResult(t *testing.T) {
+	in := []*big.Int{big.NewInt(1), big.NewInt(2)}
+
+	// warm up the pool.
+	if _, err := Hash(in); err != nil {
+		t.Fatalf("Hash: %v", err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := Hash(in); err != nil {
+			t.Fatalf("Hash: %v", err)
+		}
+	})
+	// Hash always returns a freshly allocated *big.Int (plus its backing
+	// word slice), unlike Hasher.Sum(dst) which can be driven allocation
+	// free; that's the one allocation we still expect here.
+	if allocs > 2 {
+		t.Fatalf("Hash allocated %v times per run once the pool is warm, want at most 2 (the result)", allocs)
+	}
+}
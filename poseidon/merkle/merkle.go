@@ -0,0 +1,177 @@
+// Package merkle builds Merkle trees over the Poseidon hash. Correct domain
+// separation and field-element handling for a tree built on top of Poseidon
+// belong next to the hash itself, rather than being re-implemented by every
+// caller.
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// BuildTree hashes leaves into a Merkle tree of the given arity (2, 4, 8 or
+// 16, matching the input widths Poseidon supports) and returns the root
+// along with every layer, layers[0] being leaves and the last entry being
+// []*big.Int{root}. A layer whose length is not a multiple of arity has its
+// final chunk padded with a zero leaf; use
+// NewTreeBuilder directly to choose a different EmptyLeaf or to call Prove
+// afterwards.
+func BuildTree(leaves []*big.Int, arity int) (*big.Int, [][]*big.Int, error) {
+	tb, err := NewTreeBuilder(arity, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tb.Build(leaves)
+}
+
+// TreeBuilder builds Merkle trees of a fixed arity over Poseidon, hashing
+// each layer across a pool of workers sized to runtime.GOMAXPROCS. Each
+// worker owns its own *poseidon.Hasher (see poseidon.NewHasher) so that
+// workers never contend on a single shared allocation arena.
+type TreeBuilder struct {
+	// Arity is the number of children per node: 2, 4, 8 or 16.
+	Arity int
+	// EmptyLeaf pads the final chunk of a layer whose length is not a
+	// multiple of Arity.
+	EmptyLeaf *big.Int
+
+	layers [][]*big.Int // set by Build; needed by Prove
+}
+
+// NewTreeBuilder returns a TreeBuilder for the given arity. emptyLeaf pads
+// the final chunk of a layer whose length is not a multiple of arity; if nil,
+// big.NewInt(0) is used.
+func NewTreeBuilder(arity int, emptyLeaf *big.Int) (*TreeBuilder, error) {
+	switch arity {
+	case 2, 4, 8, 16:
+	default:
+		return nil, fmt.Errorf("merkle: unsupported arity %d, must be one of 2, 4, 8, 16", arity)
+	}
+	if emptyLeaf == nil {
+		emptyLeaf = big.NewInt(0)
+	}
+	return &TreeBuilder{Arity: arity, EmptyLeaf: emptyLeaf}, nil
+}
+
+// Build hashes leaves layer by layer until a single root remains, recording
+// every layer so that Prove can be called afterwards. The root is always the
+// output of at least one Poseidon hash, even for a single leaf: hashLayer
+// pads a lone leaf with EmptyLeaf the same way it pads the final chunk of any
+// odd-length layer, so a one-leaf root can never be mistaken for (or forged
+// as) a raw, unhashed leaf value.
+func (tb *TreeBuilder) Build(leaves []*big.Int) (*big.Int, [][]*big.Int, error) {
+	if len(leaves) == 0 {
+		return nil, nil, errors.New("merkle: cannot build a tree with no leaves")
+	}
+
+	layers := [][]*big.Int{leaves}
+	cur := leaves
+	for len(cur) > 1 || len(layers) == 1 {
+		next, err := tb.hashLayer(cur)
+		if err != nil {
+			return nil, nil, err
+		}
+		layers = append(layers, next)
+		cur = next
+	}
+
+	tb.layers = layers
+	return cur[0], layers, nil
+}
+
+// hashLayer groups cur into Arity-sized chunks (padding the final chunk with
+// EmptyLeaf) and hashes each chunk across the worker pool.
+func (tb *TreeBuilder) hashLayer(cur []*big.Int) ([]*big.Int, error) {
+	nChunks := (len(cur) + tb.Arity - 1) / tb.Arity
+	out := make([]*big.Int, nChunks)
+	errs := make([]error, nChunks)
+
+	workers := runtime.GOMAXPROCS(0)
+	if workers > nChunks {
+		workers = nChunks
+	}
+
+	chunkIdx := make(chan int, nChunks)
+	for i := 0; i < nChunks; i++ {
+		chunkIdx <- i
+	}
+	close(chunkIdx)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			h := poseidon.NewHasher(tb.Arity + 1)
+			chunk := make([]*big.Int, tb.Arity)
+			for i := range chunkIdx {
+				start := i * tb.Arity
+				for j := 0; j < tb.Arity; j++ {
+					if start+j < len(cur) {
+						chunk[j] = cur[start+j]
+					} else {
+						chunk[j] = tb.EmptyLeaf
+					}
+				}
+				h.Reset()
+				if err := h.Write(chunk); err != nil {
+					errs[i] = err
+					continue
+				}
+				out[i] = h.Sum(nil)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// Prove returns a Merkle inclusion proof for the leaf at index: for each
+// layer from the leaves up to the root, the Arity-1 sibling hashes of the
+// leaf's chunk (in chunk order, skipping the leaf's own slot) and the
+// leaf's position within that chunk. A verifier can recompute the root from
+// these without re-implementing the tree's layout. Build must be called
+// first.
+func (tb *TreeBuilder) Prove(index int) ([]*big.Int, []uint8, error) {
+	if tb.layers == nil {
+		return nil, nil, errors.New("merkle: Build must be called before Prove")
+	}
+	leaves := tb.layers[0]
+	if index < 0 || index >= len(leaves) {
+		return nil, nil, fmt.Errorf("merkle: index %d out of range [0, %d)", index, len(leaves))
+	}
+
+	var siblings []*big.Int
+	var positions []uint8
+
+	idx := index
+	for layer := 0; layer < len(tb.layers)-1; layer++ {
+		cur := tb.layers[layer]
+		chunkStart := (idx / tb.Arity) * tb.Arity
+		pos := idx % tb.Arity
+		for j := 0; j < tb.Arity; j++ {
+			if j == pos {
+				continue
+			}
+			if chunkStart+j < len(cur) {
+				siblings = append(siblings, cur[chunkStart+j])
+			} else {
+				siblings = append(siblings, tb.EmptyLeaf)
+			}
+		}
+		positions = append(positions, uint8(pos))
+		idx /= tb.Arity
+	}
+	return siblings, positions, nil
+}
@@ -0,0 +1,175 @@
+package merkle
+
+import (
+	"math/big"
+	"runtime"
+	"testing"
+
+	"github.com/iden3/go-iden3-crypto/poseidon"
+)
+
+// reconstructRoot recomputes the root for leaf index from the proof returned
+// by Prove, without relying on any TreeBuilder state, so it actually checks
+// the proof is self-contained and correct rather than just round-tripping
+// through the same code path that produced it.
+func reconstructRoot(t *testing.T, leaf *big.Int, index, arity int, siblings []*big.Int, positions []uint8) *big.Int {
+	t.Helper()
+	cur := leaf
+	for layer, pos := range positions {
+		chunk := make([]*big.Int, arity)
+		siblingsForLayer := siblings[layer*(arity-1) : (layer+1)*(arity-1)]
+		si := 0
+		for j := 0; j < arity; j++ {
+			if j == int(pos) {
+				chunk[j] = cur
+				continue
+			}
+			chunk[j] = siblingsForLayer[si]
+			si++
+		}
+		h, err := poseidon.Hash(chunk)
+		if err != nil {
+			t.Fatalf("poseidon.Hash: %v", err)
+		}
+		cur = h
+	}
+	return cur
+}
+
+func TestBuildTreeAndProveRoundTrip(t *testing.T) {
+	leaves := make([]*big.Int, 11) // odd and not a power of the arity, to exercise padding
+	for i := range leaves {
+		leaves[i] = big.NewInt(int64(i + 1))
+	}
+
+	for _, arity := range []int{2, 4, 8, 16} {
+		root, layers, err := BuildTree(leaves, arity)
+		if err != nil {
+			t.Fatalf("arity %d: BuildTree: %v", arity, err)
+		}
+		if len(layers[0]) != len(leaves) {
+			t.Fatalf("arity %d: layers[0] has %d leaves, want %d", arity, len(layers[0]), len(leaves))
+		}
+		if len(layers[len(layers)-1]) != 1 || layers[len(layers)-1][0].Cmp(root) != 0 {
+			t.Fatalf("arity %d: last layer does not contain the returned root", arity)
+		}
+
+		tb, err := NewTreeBuilder(arity, nil)
+		if err != nil {
+			t.Fatalf("arity %d: NewTreeBuilder: %v", arity, err)
+		}
+		gotRoot, _, err := tb.Build(leaves)
+		if err != nil {
+			t.Fatalf("arity %d: Build: %v", arity, err)
+		}
+		if gotRoot.Cmp(root) != 0 {
+			t.Fatalf("arity %d: BuildTree and TreeBuilder.Build disagree on the root", arity)
+		}
+
+		for _, index := range []int{0, 1, len(leaves) - 1} {
+			siblings, positions, err := tb.Prove(index)
+			if err != nil {
+				t.Fatalf("arity %d: Prove(%d): %v", arity, index, err)
+			}
+			if len(positions) != len(layers)-1 {
+				t.Fatalf("arity %d: Prove(%d) returned %d layers of positions, want %d", arity, index, len(positions), len(layers)-1)
+			}
+			got := reconstructRoot(t, leaves[index], index, arity, siblings, positions)
+			if got.Cmp(root) != 0 {
+				t.Fatalf("arity %d: proof for index %d reconstructed root %v, want %v", arity, index, got, root)
+			}
+		}
+	}
+}
+
+func TestProveBeforeBuildErrors(t *testing.T) {
+	tb, err := NewTreeBuilder(2, nil)
+	if err != nil {
+		t.Fatalf("NewTreeBuilder: %v", err)
+	}
+	if _, _, err := tb.Prove(0); err == nil {
+		t.Fatal("Prove before Build should have errored")
+	}
+}
+
+func TestBuildTreeSingleLeafRootIsHashed(t *testing.T) {
+	leaf := big.NewInt(42)
+	for _, arity := range []int{2, 4, 8, 16} {
+		tb, err := NewTreeBuilder(arity, nil)
+		if err != nil {
+			t.Fatalf("arity %d: NewTreeBuilder: %v", arity, err)
+		}
+		root, layers, err := tb.Build([]*big.Int{leaf})
+		if err != nil {
+			t.Fatalf("arity %d: Build: %v", arity, err)
+		}
+		if root.Cmp(leaf) == 0 {
+			t.Fatalf("arity %d: single-leaf root %v equals the raw leaf, want a hash of it", arity, root)
+		}
+		if len(layers) != 2 {
+			t.Fatalf("arity %d: single-leaf tree has %d layers, want 2 (leaf, hashed root)", arity, len(layers))
+		}
+
+		siblings, positions, err := tb.Prove(0)
+		if err != nil {
+			t.Fatalf("arity %d: Prove(0): %v", arity, err)
+		}
+		got := reconstructRoot(t, leaf, 0, arity, siblings, positions)
+		if got.Cmp(root) != 0 {
+			t.Fatalf("arity %d: proof for the single leaf reconstructed root %v, want %v", arity, got, root)
+		}
+	}
+}
+
+func TestProveIndexOutOfRange(t *testing.T) {
+	tb, err := NewTreeBuilder(2, nil)
+	if err != nil {
+		t.Fatalf("NewTreeBuilder: %v", err)
+	}
+	leaves := []*big.Int{big.NewInt(1), big.NewInt(2), big.NewInt(3)}
+	if _, _, err := tb.Build(leaves); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if _, _, err := tb.Prove(len(leaves)); err == nil {
+		t.Fatal("Prove with an out-of-range index should have errored")
+	}
+}
+
+// TestHashLayerConcurrencyMatchesSequential forces the worker pool in
+// hashLayer to run with more than one goroutine (enough chunks, GOMAXPROCS
+// raised above 1) and checks the result is identical, deterministic and
+// race-free across repeated runs, since each worker hashes with its own
+// *poseidon.Hasher over a shared output slice.
+func TestHashLayerConcurrencyMatchesSequential(t *testing.T) {
+	prev := runtime.GOMAXPROCS(4)
+	defer runtime.GOMAXPROCS(prev)
+
+	const arity = 2
+	leaves := make([]*big.Int, 257) // many chunks, so every worker gets work
+	for i := range leaves {
+		leaves[i] = big.NewInt(int64(i + 1))
+	}
+
+	tb, err := NewTreeBuilder(arity, nil)
+	if err != nil {
+		t.Fatalf("NewTreeBuilder: %v", err)
+	}
+	want, _, err := tb.Build(leaves)
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		tb2, err := NewTreeBuilder(arity, nil)
+		if err != nil {
+			t.Fatalf("NewTreeBuilder: %v", err)
+		}
+		got, _, err := tb2.Build(leaves)
+		if err != nil {
+			t.Fatalf("run %d: Build: %v", i, err)
+		}
+		if got.Cmp(want) != 0 {
+			t.Fatalf("run %d: concurrent Build produced %v, want %v", i, got, want)
+		}
+	}
+}
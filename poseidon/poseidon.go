@@ -45,12 +45,14 @@ func ark(state, c []*ff.Element, it int) {
 }
 
 // mix returns [[matrix]] * [vector]
-// while utilizing the scratch space to save on allocations
-func mixWithScratch(state []*ff.Element, m [][]*ff.Element, scratch []*ff.Element) []*ff.Element {
+// while utilizing the scratch space and the tmp temporary to save on
+// allocations; tmp is clobbered on every call and must not alias state,
+// scratch or m.
+func mixWithScratch(state []*ff.Element, m [][]*ff.Element, scratch []*ff.Element, tmp *ff.Element) []*ff.Element {
 	for i := 0; i < len(state); i++ {
 		scratch[i].SetZero()
 		for j := 0; j < len(state); j++ {
-			scratch[i].Add(scratch[i], new(ff.Element).Mul(m[j][i], state[j]))
+			scratch[i].Add(scratch[i], tmp.Mul(m[j][i], state[j]))
 		}
 	}
 	for i := 0; i < len(state); i++ {
@@ -69,7 +71,12 @@ func HashWithState(inpBI []*big.Int, initState *big.Int) (*big.Int, error) {
 }
 
 // OBS: assumes scratch and state are of equal length
-func hashElements(state []*ff.Element, scratch []*ff.Element) []*ff.Element {
+//
+// tmp and newState0 are arena-backed ff.Element temporaries owned by the
+// caller (see Hasher): reusing them instead of allocating a fresh
+// *ff.Element per multiplication is what keeps this function allocation-free
+// once the caller's arena is warm.
+func hashElements(state []*ff.Element, scratch []*ff.Element, tmp, newState0 *ff.Element) []*ff.Element {
 	t := len(state)
 	nRoundsF := NROUNDSF
 	nRoundsP := NROUNDSP[t-2]
@@ -83,12 +90,11 @@ func hashElements(state []*ff.Element, scratch []*ff.Element) []*ff.Element {
 	for i := 0; i < nRoundsF/2-1; i++ {
 		exp5state(state)
 		ark(state, C, (i+1)*t)
-		state = mixWithScratch(state, M, scratch)
+		state = mixWithScratch(state, M, scratch, tmp)
 	}
 	exp5state(state)
 	ark(state, C, (nRoundsF/2)*t)
-	state = mixWithScratch(state, P, scratch)
-	newState0 := zero()
+	state = mixWithScratch(state, P, scratch, tmp)
 
 	for i := 0; i < nRoundsP; i++ {
 		exp5(state[0])
@@ -96,11 +102,11 @@ func hashElements(state []*ff.Element, scratch []*ff.Element) []*ff.Element {
 
 		newState0.SetZero()
 		for j := 0; j < len(state); j++ {
-			newState0.Add(newState0, new(ff.Element).Mul(S[(t*2-1)*i+j], state[j]))
+			newState0.Add(newState0, tmp.Mul(S[(t*2-1)*i+j], state[j]))
 		}
 
 		for k := 1; k < t; k++ {
-			state[k] = state[k].Add(state[k], new(ff.Element).Mul(state[0], S[(t*2-1)*i+t+k-1]))
+			state[k] = state[k].Add(state[k], tmp.Mul(state[0], S[(t*2-1)*i+t+k-1]))
 		}
 		state[0].Set(newState0)
 	}
@@ -108,10 +114,10 @@ func hashElements(state []*ff.Element, scratch []*ff.Element) []*ff.Element {
 	for i := 0; i < nRoundsF/2-1; i++ {
 		exp5state(state)
 		ark(state, C, (nRoundsF/2+1)*t+nRoundsP+i*t)
-		state = mixWithScratch(state, M, scratch)
+		state = mixWithScratch(state, M, scratch, tmp)
 	}
 	exp5state(state)
-	state = mixWithScratch(state, M, scratch)
+	state = mixWithScratch(state, M, scratch, tmp)
 	return state
 }
 
@@ -123,7 +129,7 @@ func hashWithStateExBytes(state []*ff.Element, scratch []*ff.Element) (*ff.Eleme
 		return nil, fmt.Errorf("invalid inputs length %d, max %d", len(state), len(NROUNDSP))
 	}
 
-	state = hashElements(state, scratch)
+	state = hashElements(state, scratch, new(ff.Element), new(ff.Element))
 
 	return state[0], nil
 }
@@ -154,7 +160,7 @@ func HashWithStateEx(inpBI []*big.Int, initState *big.Int, nOuts int) ([]*big.In
 		scratch[i] = zero()
 	}
 
-	state = hashElements(state, scratch)
+	state = hashElements(state, scratch, new(ff.Element), new(ff.Element))
 
 	r := make([]*big.Int, nOuts)
 	for i := 0; i < nOuts; i++ {
@@ -165,9 +171,23 @@ func HashWithStateEx(inpBI []*big.Int, initState *big.Int, nOuts int) ([]*big.In
 	return r, nil
 }
 
-// Hash computes the Poseidon hash for the given inputs
+// Hash computes the Poseidon hash for the given inputs. It borrows a
+// *Hasher from the package's Hasher pool, so repeated calls skip the
+// state/scratch allocations HashWithStateEx makes; the *big.Int it returns
+// is still a fresh allocation per call. Callers who also want to avoid that
+// last allocation should drive a reused Hasher directly with Sum(dst).
 func Hash(inpBI []*big.Int) (*big.Int, error) {
-	return HashWithState(inpBI, big.NewInt(0))
+	if len(inpBI) == 0 || len(inpBI) > len(NROUNDSP) {
+		return nil, fmt.Errorf("invalid inputs length %d, max %d", len(inpBI), len(NROUNDSP))
+	}
+	t := len(inpBI) + 1
+	h := getHasher(t)
+	defer putHasher(t, h)
+
+	if err := h.Write(inpBI); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
 }
 
 // HashEx computes the Poseidon hash for the given inputs and returns
@@ -0,0 +1,196 @@
+package poseidon
+
+import (
+	"fmt"
+	"hash"
+	"math/big"
+
+	"github.com/iden3/go-iden3-crypto/ff"
+)
+
+// Sponge is a streaming Poseidon sponge implementing the standard hash.Hash
+// contract (io.Writer, Sum, Reset, Size, BlockSize), so a message can be
+// absorbed incrementally instead of being buffered whole in memory before
+// calling HashBytesX / HashBytesXLessAlloc. It applies the same 31-byte
+// chunking and zero-padding rule as HashBytesXLessAlloc.
+//
+// Sponge is not safe for concurrent use.
+type Sponge struct {
+	frameSize int
+
+	state   []*ff.Element
+	scratch []*ff.Element
+	tmp     *ff.Element
+	newSt0  *ff.Element
+
+	k     int // next state slot to fill: starts at 0, then 1..frameSize-1
+	dirty bool
+
+	buf  [spongeChunkSize]byte // partial (< 31 byte) chunk not yet absorbed
+	bufN int
+
+	last ff.Element // digest of the last finalized frame, for Sum()
+}
+
+// NewSponge returns a Poseidon sponge configured with the given frame size
+// (2-16, same range as HashBytesX's frameSize), ready to absorb bytes via
+// Write and finalize via Sum. It panics if frameSize is out of range, in
+// keeping with other hash.Hash constructors in the standard library that
+// reject invalid configuration up front rather than returning an error.
+//
+// The concrete type returned is *Sponge; callers that want the field element
+// digest instead of its byte encoding can type-assert and call SumBig.
+func NewSponge(frameSize int) hash.Hash {
+	if frameSize < 2 || frameSize > 16 {
+		panic(fmt.Sprintf("poseidon: incorrect frame size %d", frameSize))
+	}
+	s := &Sponge{
+		frameSize: frameSize,
+		state:     make([]*ff.Element, frameSize+1),
+		scratch:   make([]*ff.Element, frameSize+1),
+		tmp:       new(ff.Element),
+		newSt0:    new(ff.Element),
+	}
+	for i := range s.state {
+		s.state[i] = new(ff.Element)
+		s.scratch[i] = new(ff.Element)
+	}
+	s.Reset()
+	return s
+}
+
+// Write absorbs p into the sponge, buffering any partial 31-byte chunk
+// internally. It never returns an error.
+func (s *Sponge) Write(p []byte) (int, error) {
+	n := len(p)
+	for len(p) > 0 {
+		take := spongeChunkSize - s.bufN
+		if take > len(p) {
+			take = len(p)
+		}
+		copy(s.buf[s.bufN:], p[:take])
+		s.bufN += take
+		p = p[take:]
+
+		if s.bufN == spongeChunkSize {
+			s.absorbChunk(s.buf[:])
+			s.bufN = 0
+		}
+	}
+	return n, nil
+}
+
+// absorbChunk feeds one full 31-byte chunk into the sponge state, running
+// the permutation whenever the frame fills up.
+func (s *Sponge) absorbChunk(chunk []byte) {
+	s.dirty = true
+	s.state[s.k].SetBytesLessMod(chunk)
+	if s.k == s.frameSize-1 {
+		s.permute()
+		s.k = 1
+	} else {
+		s.k++
+	}
+}
+
+// permute runs the Poseidon permutation over the current frame, chains the
+// resulting digest into state[0] of the next frame, and zeroes the rest of
+// the frame.
+func (s *Sponge) permute() {
+	for i := range s.scratch {
+		s.scratch[i].SetZero()
+	}
+	state := hashElements(s.state, s.scratch, s.tmp, s.newSt0)
+	s.last.Set(state[0])
+	s.dirty = false
+
+	s.state[0].Set(&s.last)
+	for j := 1; j < len(s.state); j++ {
+		s.state[j].SetZero()
+	}
+}
+
+// clone returns a private copy of the sponge's absorption state (frame,
+// buffered tail, chaining digest) that Sum/SumBig can finalize in place of
+// the receiver, so finalizing does not disturb s: repeated Sum calls are
+// idempotent and further Writes continue the original, unfinalized message.
+func (s *Sponge) clone() *Sponge {
+	c := &Sponge{
+		frameSize: s.frameSize,
+		state:     make([]*ff.Element, len(s.state)),
+		scratch:   make([]*ff.Element, len(s.scratch)),
+		tmp:       new(ff.Element),
+		newSt0:    new(ff.Element),
+		k:         s.k,
+		dirty:     s.dirty,
+		buf:       s.buf,
+		bufN:      s.bufN,
+	}
+	for i := range c.state {
+		c.state[i] = new(ff.Element).Set(s.state[i])
+		c.scratch[i] = new(ff.Element)
+	}
+	c.last.Set(&s.last)
+	return c
+}
+
+// finalize absorbs any buffered partial chunk (zero-padded, same rule as
+// HashBytesXLessAlloc) into a clone of s and runs a final permutation if
+// needed, returning the clone so the caller can read its digest without
+// mutating s.
+func (s *Sponge) finalize() *Sponge {
+	c := s.clone()
+	if c.bufN > 0 {
+		var padded [spongeChunkSize]byte
+		copy(padded[:], c.buf[:c.bufN])
+		c.absorbChunk(padded[:])
+	}
+	if c.dirty {
+		c.permute()
+	}
+	return c
+}
+
+// Sum finalizes the sponge and appends the digest to b, per the hash.Hash
+// contract. It does not change the underlying hash state: Sum may be called
+// repeatedly (returning the same digest each time), and interleaved with
+// further Writes to hash the concatenation of everything written so far.
+func (s *Sponge) Sum(b []byte) []byte {
+	digest := s.finalize().last.Bytes()
+	return append(b, digest[:]...)
+}
+
+// SumBig finalizes the sponge like Sum, but returns the digest as the
+// underlying field element instead of its byte encoding. Like Sum, it does
+// not mutate the Sponge.
+func (s *Sponge) SumBig() *big.Int {
+	r := big.NewInt(0)
+	s.finalize().last.ToBigIntRegular(r)
+	return r
+}
+
+// Reset restores the Sponge to its initial, empty state so it can absorb a
+// new message.
+func (s *Sponge) Reset() {
+	for i := range s.state {
+		s.state[i].SetZero()
+	}
+	s.k = 0
+	s.dirty = false
+	s.bufN = 0
+	s.last.SetZero()
+}
+
+// elementSize is the fixed byte length of a serialized ff.Element digest.
+const elementSize = 32
+
+// Size returns the length, in bytes, of a digest returned by Sum.
+func (s *Sponge) Size() int {
+	return elementSize
+}
+
+// BlockSize returns the sponge's absorption block size: spongeChunkSize (31)
+// bytes per field element.
+func (s *Sponge) BlockSize() int {
+	return spongeChunkSize
+}
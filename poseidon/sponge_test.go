@@ -0,0 +1,87 @@
+package poseidon
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSpongeMatchesHashBytesXLessAlloc(t *testing.T) {
+	msg := bytes.Repeat([]byte("poseidon sponge streaming test message "), 5)
+
+	want, err := HashBytesXLessAlloc(msg, 16)
+	if err != nil {
+		t.Fatalf("HashBytesXLessAlloc: %v", err)
+	}
+
+	sp := NewSponge(16)
+	if _, err := sp.Write(msg); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := sp.Sum(nil)
+
+	if !bytes.Equal(want, got) {
+		t.Fatalf("Sponge digest = %x, want %x", got, want)
+	}
+}
+
+func TestSpongeSumIsIdempotent(t *testing.T) {
+	sp := NewSponge(16)
+	if _, err := sp.Write([]byte("some message that does not align to 31 bytes")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	d1 := sp.Sum(nil)
+	d2 := sp.Sum(nil)
+	if !bytes.Equal(d1, d2) {
+		t.Fatalf("Sum is not idempotent: %x != %x", d1, d2)
+	}
+}
+
+func TestSpongeSumDoesNotMutateState(t *testing.T) {
+	part1 := []byte("first part of the message, ")
+	part2 := []byte("second part of the message")
+
+	sp := NewSponge(16)
+	if _, err := sp.Write(part1); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	_ = sp.Sum(nil) // must not finalize the real state
+
+	if _, err := sp.Write(part2); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := sp.Sum(nil)
+
+	want, err := HashBytesXLessAlloc(append(append([]byte{}, part1...), part2...), 16)
+	if err != nil {
+		t.Fatalf("HashBytesXLessAlloc: %v", err)
+	}
+
+	if !bytes.Equal(want, got) {
+		t.Fatalf("Sum after interleaved Write = %x, want %x (hash of the full concatenation)", got, want)
+	}
+}
+
+func TestSpongeReset(t *testing.T) {
+	sp := NewSponge(16).(*Sponge)
+	if _, err := sp.Write([]byte("some message")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	_ = sp.Sum(nil)
+
+	sp.Reset()
+	if _, err := sp.Write([]byte("some message")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := sp.Sum(nil)
+
+	sp2 := NewSponge(16)
+	if _, err := sp2.Write([]byte("some message")); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	want := sp2.Sum(nil)
+
+	if !bytes.Equal(want, got) {
+		t.Fatalf("digest after Reset = %x, want %x", got, want)
+	}
+}